@@ -0,0 +1,47 @@
+// Command gdax-trader indexes live GDAX market data into the configured
+// output sinks. See pkg/server for the actual lifecycle.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+
+	"github.com/pwaterz/gdax-trader/pkg/config"
+	"github.com/pwaterz/gdax-trader/pkg/log"
+	"github.com/pwaterz/gdax-trader/pkg/server"
+)
+
+var configLocation = flag.String("config", "./config.yml", "Location of yaml configuration file")
+
+var logMain = log.For("main")
+
+func main() {
+	flag.Parse()
+
+	cfg, err := config.New(*configLocation)
+	if err != nil {
+		logMain.Fatal(err)
+	}
+
+	srv, err := server.New(cfg)
+	if err != nil {
+		logMain.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go waitForSignal(cancel)
+
+	if err := srv.Run(ctx); err != nil {
+		logMain.Fatal(err)
+	}
+}
+
+func waitForSignal(cancel context.CancelFunc) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	<-c
+	logMain.Info("Got interrupt signal. Initiating shutdown.")
+	cancel()
+}
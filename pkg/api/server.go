@@ -0,0 +1,334 @@
+// Package api exposes the internal ticker and order-book pipelines to other
+// services over gRPC, so they don't have to poll Elasticsearch for live
+// market data. A Server is itself an output.Output: wiring it in as a sink
+// turns ES indexing into just one of potentially many subscribers behind the
+// same fan-out.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/pwaterz/gdax-trader/pkg/config"
+	"github.com/pwaterz/gdax-trader/pkg/log"
+	"github.com/pwaterz/gdax-trader/pkg/metrics"
+)
+
+var logAPI = log.For("api")
+
+// defaultSubscriberBuffer is used when a GRPCConfig doesn't set one.
+const defaultSubscriberBuffer = 16
+
+// bookSubscriber is a single SubscribeBook caller's channel, bounded to ch's
+// capacity, along with the depth it asked for.
+type bookSubscriber struct {
+	ch    chan *BookUpdate
+	depth int
+}
+
+// Server implements MarketDataServer, serving live ticker and order-book
+// updates to gRPC subscribers, and output.Output, so it can be wired into
+// the existing write path alongside (or instead of) Elasticsearch.
+type Server struct {
+	subscriberBuffer int
+
+	grpcServer *grpc.Server
+	listener   net.Listener
+
+	mu         sync.Mutex
+	tickerSubs map[string][]chan *Tick
+	bookSubs   map[string][]*bookSubscriber
+	bbo        map[string]*BBO
+}
+
+// New starts a gRPC server listening on cfg.ListenAddress and returns a
+// Server ready to accept subscribers and receive writes.
+func New(cfg *config.GRPCConfig) (*Server, error) {
+	listener, err := net.Listen("tcp", cfg.ListenAddress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not listen on %v", cfg.ListenAddress)
+	}
+
+	buf := cfg.SubscriberBuffer
+	if buf == 0 {
+		buf = defaultSubscriberBuffer
+	}
+
+	s := &Server{
+		subscriberBuffer: buf,
+		grpcServer:       grpc.NewServer(),
+		listener:         listener,
+		tickerSubs:       make(map[string][]chan *Tick),
+		bookSubs:         make(map[string][]*bookSubscriber),
+		bbo:              make(map[string]*BBO),
+	}
+
+	RegisterMarketDataServer(s.grpcServer, s)
+
+	go func() {
+		if err := s.grpcServer.Serve(listener); err != nil {
+			logAPI.Error(errors.Wrap(err, "grpc server stopped"))
+		}
+	}()
+
+	logAPI.Info("Listening for gRPC market data subscribers on " + cfg.ListenAddress)
+	return s, nil
+}
+
+// SubscribeTicker implements MarketDataServer. It streams every ticker
+// update for req.ProductId until the client disconnects or ctx is done.
+// Slow subscribers are dropped rather than allowed to block ingestion.
+func (s *Server) SubscribeTicker(req *SubscribeTickerRequest, stream MarketData_SubscribeTickerServer) error {
+	ch := make(chan *Tick, s.subscriberBuffer)
+	s.addTickerSub(req.ProductId, ch)
+	defer s.removeTickerSub(req.ProductId, ch)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case tick := <-ch:
+			if err := stream.Send(tick); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// SubscribeBook implements MarketDataServer. It streams order-book updates
+// for req.ProductId, truncated to req.Depth price levels per side, until the
+// client disconnects or ctx is done.
+func (s *Server) SubscribeBook(req *SubscribeBookRequest, stream MarketData_SubscribeBookServer) error {
+	sub := &bookSubscriber{
+		ch:    make(chan *BookUpdate, s.subscriberBuffer),
+		depth: int(req.Depth),
+	}
+	s.addBookSub(req.ProductId, sub)
+	defer s.removeBookSub(req.ProductId, sub)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case update := <-sub.ch:
+			if err := stream.Send(update); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// GetTopOfBook implements MarketDataServer, returning the best bid/offer
+// last seen for req.ProductId.
+func (s *Server) GetTopOfBook(ctx context.Context, req *GetTopOfBookRequest) (*BBO, error) {
+	s.mu.Lock()
+	bbo, ok := s.bbo[req.ProductId]
+	if !ok {
+		s.mu.Unlock()
+		return &BBO{ProductId: req.ProductId}, nil
+	}
+	out := copyBBO(bbo)
+	s.mu.Unlock()
+
+	return out, nil
+}
+
+// copyBBO returns a deep copy of bbo so callers can read it after releasing
+// s.mu without racing writeBook, which mutates the stored BBO in place.
+func copyBBO(bbo *BBO) *BBO {
+	out := &BBO{ProductId: bbo.ProductId}
+	if bbo.BestBid != nil {
+		bid := *bbo.BestBid
+		out.BestBid = &bid
+	}
+	if bbo.BestAsk != nil {
+		ask := *bbo.BestAsk
+		out.BestAsk = &ask
+	}
+	return out
+}
+
+// Write implements output.Output. doc is whatever the ticker or order-book
+// indexer produced; fields are extracted via a JSON round trip since those
+// types aren't exported for direct use here.
+func (s *Server) Write(ctx context.Context, doctype string, doc interface{}) error {
+	switch doctype {
+	case "ticker":
+		return s.writeTicker(doc)
+	case "order-book":
+		return s.writeBook(doc)
+	}
+	return nil
+}
+
+// Flush implements output.Output. There's nothing to buffer: every Write
+// fans straight out to subscriber channels.
+func (s *Server) Flush() error {
+	return nil
+}
+
+// Close implements output.Output, stopping the gRPC server.
+func (s *Server) Close() error {
+	s.grpcServer.GracefulStop()
+	return nil
+}
+
+type tickerFields struct {
+	ProductID string `json:"product_id"`
+	Price     string `json:"price"`
+	BestBid   string `json:"best_bid"`
+	BestAsk   string `json:"best_ask"`
+	Time      string `json:"time"`
+}
+
+func (s *Server) writeTicker(doc interface{}) error {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	var f tickerFields
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return err
+	}
+	if f.ProductID == "" {
+		return nil
+	}
+
+	tick := &Tick{
+		ProductId: f.ProductID,
+		Price:     f.Price,
+		BestBid:   f.BestBid,
+		BestAsk:   f.BestAsk,
+		Time:      f.Time,
+	}
+
+	s.mu.Lock()
+	subs := s.tickerSubs[f.ProductID]
+	s.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- tick:
+		default:
+			metrics.RecordMessageDropped(f.ProductID, "ticker")
+			logAPI.Warn("Dropping ticker update for slow subscriber on " + f.ProductID)
+		}
+	}
+	return nil
+}
+
+type bookLevel struct {
+	Price float64 `json:"Price"`
+	Size  float64 `json:"Size"`
+}
+
+type bookFields struct {
+	ProductID string      `json:"product_id"`
+	Bids      []bookLevel `json:"bids"`
+	Asks      []bookLevel `json:"asks"`
+	Mid       float64     `json:"mid_price"`
+	Spread    float64     `json:"spread"`
+}
+
+func (s *Server) writeBook(doc interface{}) error {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	var f bookFields
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return err
+	}
+	if f.ProductID == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	if len(f.Bids) > 0 {
+		bbo, ok := s.bbo[f.ProductID]
+		if !ok {
+			bbo = &BBO{ProductId: f.ProductID}
+			s.bbo[f.ProductID] = bbo
+		}
+		bbo.BestBid = &PriceLevel{Price: f.Bids[0].Price, Size: f.Bids[0].Size}
+	}
+	if len(f.Asks) > 0 {
+		bbo, ok := s.bbo[f.ProductID]
+		if !ok {
+			bbo = &BBO{ProductId: f.ProductID}
+			s.bbo[f.ProductID] = bbo
+		}
+		bbo.BestAsk = &PriceLevel{Price: f.Asks[0].Price, Size: f.Asks[0].Size}
+	}
+	subs := s.bookSubs[f.ProductID]
+	s.mu.Unlock()
+
+	for _, sub := range subs {
+		update := &BookUpdate{
+			ProductId: f.ProductID,
+			Bids:      toProtoLevels(f.Bids, sub.depth),
+			Asks:      toProtoLevels(f.Asks, sub.depth),
+			MidPrice:  f.Mid,
+			Spread:    f.Spread,
+		}
+		select {
+		case sub.ch <- update:
+		default:
+			metrics.RecordMessageDropped(f.ProductID, "order-book")
+			logAPI.Warn("Dropping book update for slow subscriber on " + f.ProductID)
+		}
+	}
+	return nil
+}
+
+func toProtoLevels(levels []bookLevel, depth int) []*PriceLevel {
+	if depth > 0 && depth < len(levels) {
+		levels = levels[:depth]
+	}
+	out := make([]*PriceLevel, len(levels))
+	for i, l := range levels {
+		out[i] = &PriceLevel{Price: l.Price, Size: l.Size}
+	}
+	return out
+}
+
+func (s *Server) addTickerSub(product string, ch chan *Tick) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tickerSubs[product] = append(s.tickerSubs[product], ch)
+}
+
+func (s *Server) removeTickerSub(product string, ch chan *Tick) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs := s.tickerSubs[product]
+	for i, c := range subs {
+		if c == ch {
+			s.tickerSubs[product] = append(subs[:i], subs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *Server) addBookSub(product string, sub *bookSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bookSubs[product] = append(s.bookSubs[product], sub)
+}
+
+func (s *Server) removeBookSub(product string, sub *bookSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs := s.bookSubs[product]
+	for i, c := range subs {
+		if c == sub {
+			s.bookSubs[product] = append(subs[:i], subs[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package backoff provides a jittered exponential backoff that doubles as a
+// backpressure gate: the same object that tells a restart loop how long to
+// wait can be paused by a downstream consumer (e.g. an overloaded
+// Elasticsearch cluster) to make producers wait too.
+package backoff
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Backoff tracks an exponentially growing delay, with full jitter, between
+// min and max. It is safe for concurrent use.
+type Backoff struct {
+	min, max time.Duration
+
+	mu          sync.Mutex
+	current     time.Duration
+	failures    int64
+	pausedUntil time.Time
+}
+
+// New returns a Backoff that grows from min up to max.
+func New(min, max time.Duration) *Backoff {
+	return &Backoff{min: min, max: max, current: min}
+}
+
+// Next returns a jittered duration to wait, chosen uniformly between 0 and
+// the current delay, then advances the backoff (doubling, capped at max) for
+// the next call.
+// Call Reset once the caller has observed sustained success.
+func (b *Backoff) Next() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	wait := b.current
+
+	b.current *= 2
+	if b.current > b.max {
+		b.current = b.max
+	}
+
+	return time.Duration(rand.Int63n(int64(wait) + 1))
+}
+
+// Reset returns the backoff to its minimum delay, e.g. after a sustained
+// run of successes.
+func (b *Backoff) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current = b.min
+}
+
+// Failures returns the rolling count of times Next has been called.
+func (b *Backoff) Failures() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures
+}
+
+// Pause tells callers of Wait to block for at least d, used to apply
+// backpressure to producers when a downstream consumer is overloaded.
+func (b *Backoff) Pause(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	until := time.Now().Add(d)
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// Wait blocks until any pause requested via Pause has elapsed, or ctx is
+// done, whichever comes first.
+func (b *Backoff) Wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		remaining := time.Until(b.pausedUntil)
+		b.mu.Unlock()
+
+		if remaining <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(remaining)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
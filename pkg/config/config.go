@@ -0,0 +1,101 @@
+// Package config loads this tool's yaml configuration file.
+package config
+
+import (
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Configuration store application configuration information
+type Configuration struct {
+	ElasticClientBatchSize     int      `yaml:"elastic-client-batch-size"`
+	ElasticClientWorkers       int      `yaml:"elastic-client-workers"`
+	ElasticClientFlushInterval int      `yaml:"elastic-client-flush-interval"`
+	ElasticClientStatsEnabled  bool     `yaml:"elastic-client-stats-enabled"`
+	Elastic                    []string `yaml:"elastic-hosts"`
+	ElasticUser                string   `yaml:"elastic-user,omitempty"`
+	ElasticPassword            string   `yaml:"elastic-password,omitempty"`
+	ElasticSniff               bool     `yaml:"elastic-sniff-discovery"`
+	ElasticIndexName           string   `yaml:"elastic-index"`
+	GDAXMarkets                []string `yaml:"gdax-markets"`
+	LogLevel                   string   `yaml:"log-level,omitempty"` // Valid values are info or debug. Defaults to info.
+
+	// Outputs lists the sinks documents are written to. If empty, defaults to
+	// a single "elastic" output so existing configurations keep working.
+	Outputs []OutputConfig `yaml:"outputs,omitempty"`
+
+	// Metrics configures the /metrics, /healthz and /readyz HTTP server. If
+	// nil, no metrics server is started.
+	Metrics *MetricsConfig `yaml:"metrics,omitempty"`
+}
+
+// MetricsConfig configures the Prometheus metrics and health-check HTTP server.
+type MetricsConfig struct {
+	ListenAddress string `yaml:"listen-address"`
+	// DisconnectThresholdSeconds is how long a market's stream can go
+	// without a message before /readyz reports not-ready. Defaults to 120.
+	DisconnectThresholdSeconds int `yaml:"disconnect-threshold-seconds,omitempty"`
+}
+
+// OutputConfig configures a single output sink. Type selects which of the
+// fields below is read; unused fields are ignored.
+type OutputConfig struct {
+	Type     string          `yaml:"type"` // elastic, kafka, file, stdout, influxdb, grpc
+	Kafka    *KafkaConfig    `yaml:"kafka,omitempty"`
+	File     *FileConfig     `yaml:"file,omitempty"`
+	InfluxDB *InfluxDBConfig `yaml:"influxdb,omitempty"`
+	GRPC     *GRPCConfig     `yaml:"grpc,omitempty"`
+}
+
+// KafkaConfig configures the "kafka" output.
+type KafkaConfig struct {
+	Brokers  []string `yaml:"brokers"`
+	ClientID string   `yaml:"client-id,omitempty"`
+}
+
+// FileConfig configures the "file" output.
+type FileConfig struct {
+	Path     string `yaml:"path"`
+	MaxBytes int64  `yaml:"max-bytes,omitempty"`
+}
+
+// InfluxDBConfig configures the "influxdb" output.
+type InfluxDBConfig struct {
+	Address  string `yaml:"address"`
+	Database string `yaml:"database"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	// FlushIntervalSeconds is how often the batch is sent to the server in
+	// the background. Defaults to 10 seconds if unset.
+	FlushIntervalSeconds int `yaml:"flush-interval-seconds,omitempty"`
+}
+
+// GRPCConfig configures the "grpc" output, which fans live ticker and
+// order-book updates out to streaming gRPC subscribers instead of (or in
+// addition to) indexing them.
+type GRPCConfig struct {
+	ListenAddress    string `yaml:"listen-address"`
+	SubscriberBuffer int    `yaml:"subscriber-buffer,omitempty"`
+}
+
+// New creates a new Configuration from the given file path.
+func New(configLocation string) (*Configuration, error) {
+	yml, err := ioutil.ReadFile(configLocation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable read configuration file %v", configLocation)
+	}
+
+	config := Configuration{}
+	err = yaml.Unmarshal(yml, &config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error parsing configuration file %v", configLocation)
+	}
+
+	if config.LogLevel == "" {
+		config.LogLevel = "info"
+	}
+
+	return &config, nil
+}
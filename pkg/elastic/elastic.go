@@ -0,0 +1,193 @@
+// Package elastic wraps the Elasticsearch client and bulk processor this
+// tool indexes documents through, including classifying bulk failures and
+// applying backpressure when the cluster is overloaded.
+package elastic
+
+import (
+	"context"
+	"io/ioutil"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	es "gopkg.in/olivere/elastic.v5"
+
+	"github.com/pwaterz/gdax-trader/pkg/backoff"
+	"github.com/pwaterz/gdax-trader/pkg/config"
+	"github.com/pwaterz/gdax-trader/pkg/log"
+	"github.com/pwaterz/gdax-trader/pkg/metrics"
+)
+
+var logElastic = log.For("elastic")
+
+// bulkStarts tracks when each in-flight bulk execution began, keyed by the
+// execution id the bulk processor's Before/After hooks share, so After can
+// report request latency.
+var (
+	bulkStartsMu sync.Mutex
+	bulkStarts   = make(map[int64]time.Time)
+)
+
+// NewClient connects to the elastic cluster described by cfg.
+func NewClient(cfg *config.Configuration) (*es.Client, error) {
+	client, err := es.NewClient(
+		es.SetURL(cfg.Elastic...),
+		es.SetSniff(cfg.ElasticSniff),
+		es.SetBasicAuth(cfg.ElasticUser, cfg.ElasticPassword),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	logElastic.Info("Elastic client successfully initialized")
+	return client, nil
+}
+
+// EnsureIndex creates cfg's index from templateFile if it doesn't already exist.
+func EnsureIndex(ctx context.Context, client *es.Client, cfg *config.Configuration, templateFile string) error {
+	logElastic.Info("Initializing index " + cfg.ElasticIndexName)
+	exists, err := client.IndexExists(cfg.ElasticIndexName).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		logElastic.Info("Index " + cfg.ElasticIndexName + " exists, nothing to do")
+		return nil
+	}
+
+	body, err := ioutil.ReadFile(templateFile)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to refresh index. Error opening %v", templateFile)
+	}
+
+	if _, err := client.CreateIndex(cfg.ElasticIndexName).BodyString(string(body)).Do(ctx); err != nil {
+		return errors.Wrapf(err, "Unable to create index. Error PUTing index definition for %v", cfg.ElasticIndexName)
+	}
+
+	logElastic.Info("Created index " + cfg.ElasticIndexName)
+	return nil
+}
+
+// NewBulkProcessor starts a bulk processor for cfg. esBackoff is paused
+// whenever the After callback sees the cluster reject a batch as overloaded,
+// and reset whenever a batch succeeds cleanly.
+func NewBulkProcessor(ctx context.Context, client *es.Client, cfg *config.Configuration, esBackoff *backoff.Backoff) (*es.BulkProcessor, error) {
+	bulk, err := client.BulkProcessor().
+		Name("embargod-indexer").
+		Before(func(id int64, requests []es.BulkableRequest) {
+			bulkStartsMu.Lock()
+			bulkStarts[id] = time.Now()
+			bulkStartsMu.Unlock()
+		}).
+		After(func(id int64, requests []es.BulkableRequest, response *es.BulkResponse, err error) {
+			bulkProcessorFinished(id, requests, response, err, esBackoff)
+		}).
+		Workers(cfg.ElasticClientWorkers).
+		BulkActions(cfg.ElasticClientBatchSize).
+		FlushInterval(time.Duration(cfg.ElasticClientFlushInterval) * time.Second).
+		Stats(cfg.ElasticClientStatsEnabled).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ElasticClientStatsEnabled {
+		go pollQueueDepth(ctx, bulk)
+	}
+
+	logElastic.Info("Elastic bulk processor successfully initialized")
+	return bulk, nil
+}
+
+// pollQueueDepth periodically reports how many documents are queued in bulk
+// until ctx is cancelled.
+func pollQueueDepth(ctx context.Context, bulk *es.BulkProcessor) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			metrics.SetBulkQueueDepth(bulk.Stats().Queued)
+		}
+	}
+}
+
+// bulkProcessorFinished is called when the elastic bulk processor finishes
+// trying to send a batch of requests. It classifies failures by HTTP status
+// and uses esBackoff to apply backpressure to producers when the cluster
+// itself is overloaded, rather than just logging every failure the same way.
+func bulkProcessorFinished(id int64, requests []es.BulkableRequest, response *es.BulkResponse, err error, esBackoff *backoff.Backoff) {
+	bulkStartsMu.Lock()
+	start, ok := bulkStarts[id]
+	delete(bulkStarts, id)
+	bulkStartsMu.Unlock()
+	if ok {
+		metrics.ObserveBulkRequestDuration(time.Since(start))
+	}
+
+	if err != nil {
+		logElastic.Error(errors.Wrap(err, "Bulk request to elastic failed"))
+		esBackoff.Pause(esBackoff.Next())
+		return
+	}
+	if response == nil {
+		return
+	}
+
+	if succeeded := response.Succeeded(); len(succeeded) > 0 {
+		logElastic.Info("Successfully sent " + strconv.Itoa(len(succeeded)) + " documents to elastic")
+		for range succeeded {
+			metrics.RecordBulkStatus(200)
+		}
+		if len(response.Failed()) == 0 {
+			esBackoff.Reset()
+		}
+	}
+
+	for _, item := range response.Failed() {
+		metrics.RecordBulkStatus(item.Status)
+	}
+
+	retryable, dropped := classifyBulkFailures(response.Failed())
+	for _, item := range dropped {
+		logElastic.Error("Dropping document rejected by elastic: " + bulkItemReason(item))
+	}
+	if len(retryable) > 0 {
+		wait := esBackoff.Next()
+		logElastic.Info("Elastic cluster reported overload on " + strconv.Itoa(len(retryable)) + " items, pausing producers for " + wait.String())
+		esBackoff.Pause(wait)
+	}
+}
+
+// classifyBulkFailures splits a bulk response's failed items by HTTP status:
+// 409 version conflicts are ignored (another writer already applied a newer
+// version of the same document), 429 and any 5xx (the cluster overloaded or
+// hit a transient internal error) should be retried once producers back
+// off, and everything else (400s and anything unrecognized) is dropped
+// since retrying it would never succeed.
+func classifyBulkFailures(items []*es.BulkResponseItem) (retryable, dropped []*es.BulkResponseItem) {
+	for _, item := range items {
+		switch {
+		case item.Status == 409:
+			continue
+		case item.Status == 429 || item.Status/100 == 5:
+			retryable = append(retryable, item)
+		default:
+			dropped = append(dropped, item)
+		}
+	}
+	return retryable, dropped
+}
+
+// bulkItemReason extracts a human readable reason from a failed bulk item.
+func bulkItemReason(item *es.BulkResponseItem) string {
+	if item.Error != nil && item.Error.Reason != "" {
+		return item.Error.Reason
+	}
+	return strconv.Itoa(item.Status)
+}
@@ -0,0 +1,63 @@
+package elastic
+
+import (
+	"testing"
+
+	es "gopkg.in/olivere/elastic.v5"
+)
+
+func TestClassifyBulkFailures(t *testing.T) {
+	items := []*es.BulkResponseItem{
+		{Status: 409},
+		{Status: 429},
+		{Status: 503},
+		{Status: 500},
+		{Status: 502},
+		{Status: 400, Error: &es.ErrorDetails{Reason: "mapper_parsing_exception"}},
+	}
+
+	retryable, dropped := classifyBulkFailures(items)
+
+	if len(retryable) != 4 {
+		t.Fatalf("expected 4 retryable items (429, 503, 500, 502), got %d", len(retryable))
+	}
+	for _, item := range retryable {
+		if item.Status != 429 && item.Status != 503 && item.Status != 500 && item.Status != 502 {
+			t.Errorf("unexpected status in retryable set: %d", item.Status)
+		}
+	}
+
+	if len(dropped) != 1 {
+		t.Fatalf("expected 1 dropped item (400), got %d", len(dropped))
+	}
+	for _, item := range dropped {
+		if item.Status != 400 {
+			t.Errorf("unexpected status in dropped set: %d", item.Status)
+		}
+	}
+}
+
+func TestClassifyBulkFailuresIgnoresConflicts(t *testing.T) {
+	items := []*es.BulkResponseItem{
+		{Status: 409},
+		{Status: 409},
+	}
+
+	retryable, dropped := classifyBulkFailures(items)
+
+	if len(retryable) != 0 || len(dropped) != 0 {
+		t.Fatalf("expected 409 conflicts to be ignored entirely, got retryable=%d dropped=%d", len(retryable), len(dropped))
+	}
+}
+
+func TestBulkItemReasonFallsBackToStatus(t *testing.T) {
+	item := &es.BulkResponseItem{Status: 400}
+	if got := bulkItemReason(item); got != "400" {
+		t.Errorf("expected fallback to status code, got %q", got)
+	}
+
+	item = &es.BulkResponseItem{Status: 400, Error: &es.ErrorDetails{Reason: "boom"}}
+	if got := bulkItemReason(item); got != "boom" {
+		t.Errorf("expected error reason, got %q", got)
+	}
+}
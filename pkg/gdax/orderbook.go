@@ -0,0 +1,18 @@
+package gdax
+
+import (
+	"context"
+
+	"github.com/pwaterz/gdax-trader/pkg/orderbook"
+	"github.com/pwaterz/gdax-trader/pkg/output"
+)
+
+// IndexOrderBook reconstructs the local order book for product from the
+// level2 snapshot + l2update stream and periodically flushes derived
+// top-of-book documents to out. See the orderbook package for the book
+// itself. It returns once the stream breaks or ctx is cancelled; callers are
+// expected to restart it (see pkg/supervisor).
+func IndexOrderBook(ctx context.Context, product string, out output.Output) error {
+	indexer := orderbook.NewIndexer(product, out, orderbook.DefaultConfig())
+	return indexer.Run(ctx)
+}
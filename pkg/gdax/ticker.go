@@ -0,0 +1,80 @@
+// Package gdax holds the per-market indexers that consume GDAX websocket
+// channels and write derived documents to an output sink.
+package gdax
+
+import (
+	"context"
+	"time"
+
+	ws "github.com/gorilla/websocket"
+	gdax "github.com/preichenberger/go-gdax"
+
+	"github.com/pwaterz/gdax-trader/pkg/log"
+	"github.com/pwaterz/gdax-trader/pkg/metrics"
+	"github.com/pwaterz/gdax-trader/pkg/output"
+)
+
+var (
+	logStream  = log.For("gdax")
+	tickerTime = time.Millisecond * 500
+)
+
+// IndexTicker indexes ticker data for product, writing it to out. It returns
+// once the stream breaks or ctx is cancelled; callers are expected to
+// restart it (see pkg/supervisor).
+func IndexTicker(ctx context.Context, product string, out output.Output) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wsDialer ws.Dialer
+	wsConn, _, err := wsDialer.Dial("wss://ws-feed.gdax.com", nil)
+	if err != nil {
+		return err
+	}
+	defer wsConn.Close()
+
+	subscribe := gdax.Message{
+		Type: "subscribe",
+		Channels: []gdax.MessageChannel{
+			gdax.MessageChannel{
+				Name:       "ticker",
+				ProductIds: []string{product},
+			},
+		},
+	}
+	if err := wsConn.WriteJSON(subscribe); err != nil {
+		return err
+	}
+
+	metrics.RecordReconnect(product, "ticker")
+
+	// Closing the connection on cancellation unblocks ReadJSON below with an
+	// error, so the function returns cooperatively instead of exiting the
+	// whole process out from under the shutdown WaitGroup. runCtx is
+	// cancelled by the deferred cancel() above when this function returns
+	// for any reason, so this goroutine never outlives the run.
+	go func() {
+		<-runCtx.Done()
+		wsConn.Close()
+	}()
+
+	message := gdax.Message{}
+	ticker := time.NewTicker(tickerTime)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := wsConn.ReadJSON(&message); err != nil {
+			return err
+		}
+		metrics.RecordMessageReceived(product, "ticker")
+
+		if message.Time.Time().IsZero() {
+			continue
+		}
+
+		if err := out.Write(ctx, "ticker", message); err != nil {
+			logStream.Error(err)
+		}
+	}
+	return nil
+}
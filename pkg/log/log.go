@@ -0,0 +1,42 @@
+// Package log centralizes logrus setup so every package in this tool shares
+// one formatter and level instead of constructing its own logger.
+package log
+
+import (
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+var base = logrus.New()
+
+func init() {
+	base.Formatter = &formatter{}
+}
+
+// formatter is an extension of the default log formatter for logrus, which strips trailing carriage returns from log messages.
+type formatter struct {
+	parentFormatter logrus.TextFormatter
+}
+
+// Format formats log messages, stripping trailing carriage returns.
+func (f *formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	entry.Message = strings.TrimSpace(entry.Message)
+	return f.parentFormatter.Format(entry)
+}
+
+// For returns a logger tagged with a "component" field, the convention every
+// package in this tool uses to identify where a log line came from.
+func For(component string) *logrus.Entry {
+	return base.WithField("component", component)
+}
+
+// SetLevel sets the logging level from a config value ("debug" or anything
+// else, which defaults to info).
+func SetLevel(level string) {
+	if level == "debug" {
+		base.Level = logrus.DebugLevel
+		return
+	}
+	base.Level = logrus.InfoLevel
+}
@@ -0,0 +1,189 @@
+// Package metrics instruments this tool's websocket ingestion and
+// Elasticsearch bulk-indexing pipelines with Prometheus metrics, and serves
+// them alongside /healthz and /readyz over HTTP.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/pwaterz/gdax-trader/pkg/log"
+)
+
+var logMetrics = log.For("metrics")
+
+var (
+	MessagesReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gdax_trader_messages_received_total",
+		Help: "Websocket messages received, by market and channel.",
+	}, []string{"market", "channel"})
+
+	MessagesDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gdax_trader_messages_dropped_total",
+		Help: "Websocket messages dropped without being indexed, by market and channel.",
+	}, []string{"market", "channel"})
+
+	Reconnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gdax_trader_stream_reconnects_total",
+		Help: "Websocket stream reconnects, by market and channel.",
+	}, []string{"market", "channel"})
+
+	BulkQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gdax_trader_bulk_queue_depth",
+		Help: "Documents currently queued in the elastic bulk processor.",
+	})
+
+	BulkRequestDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "gdax_trader_bulk_request_duration_seconds",
+		Help:    "Elastic bulk request latency.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	BulkItems = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gdax_trader_bulk_items_total",
+		Help: "Elastic bulk response items, by status class (2xx, 409, 429, 4xx, 5xx).",
+	}, []string{"status_class"})
+)
+
+var bookSpreadDesc = prometheus.NewDesc(
+	"gdax_trader_order_book_spread",
+	"Current bid/ask spread, by market.",
+	[]string{"market"}, nil,
+)
+
+var topOfBookAgeDesc = prometheus.NewDesc(
+	"gdax_trader_top_of_book_age_seconds",
+	"Seconds since the last order book update was flushed, by market.",
+	[]string{"market"}, nil,
+)
+
+// books backs the order-book spread and top-of-book age metrics, which are
+// collected at scrape time so age reflects how stale each market's book is
+// right now rather than when it was last flushed.
+var books = &bookTracker{
+	lastUpdate: make(map[string]time.Time),
+	spread:     make(map[string]float64),
+}
+
+type bookTracker struct {
+	mu         sync.Mutex
+	lastUpdate map[string]time.Time
+	spread     map[string]float64
+}
+
+func (t *bookTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- bookSpreadDesc
+	ch <- topOfBookAgeDesc
+}
+
+func (t *bookTracker) Collect(ch chan<- prometheus.Metric) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	for market, ts := range t.lastUpdate {
+		ch <- prometheus.MustNewConstMetric(bookSpreadDesc, prometheus.GaugeValue, t.spread[market], market)
+		ch <- prometheus.MustNewConstMetric(topOfBookAgeDesc, prometheus.GaugeValue, now.Sub(ts).Seconds(), market)
+	}
+}
+
+// streams backs stream-disconnect readiness tracking: the last time any
+// message was seen for a given market/channel.
+var streams = &streamTracker{lastSeen: make(map[string]time.Time)}
+
+type streamTracker struct {
+	mu       sync.Mutex
+	lastSeen map[string]time.Time
+}
+
+func init() {
+	prometheus.MustRegister(MessagesReceived, MessagesDropped, Reconnects, BulkQueueDepth, BulkRequestDuration, BulkItems, books)
+}
+
+// RecordMessageReceived counts a received websocket message for market and
+// channel (e.g. "ticker", "level2"), and marks the stream alive for
+// readiness purposes.
+func RecordMessageReceived(market, channel string) {
+	MessagesReceived.WithLabelValues(market, channel).Inc()
+
+	streams.mu.Lock()
+	streams.lastSeen[market+"/"+channel] = time.Now()
+	streams.mu.Unlock()
+}
+
+// RecordMessageDropped counts a websocket message that was received but not
+// indexed, for market and channel.
+func RecordMessageDropped(market, channel string) {
+	MessagesDropped.WithLabelValues(market, channel).Inc()
+}
+
+// RecordReconnect counts a stream reconnect for market and channel.
+func RecordReconnect(market, channel string) {
+	Reconnects.WithLabelValues(market, channel).Inc()
+}
+
+// SetBulkQueueDepth reports how many documents are currently queued in the
+// elastic bulk processor.
+func SetBulkQueueDepth(depth int64) {
+	BulkQueueDepth.Set(float64(depth))
+}
+
+// ObserveBulkRequestDuration records how long a bulk request took.
+func ObserveBulkRequestDuration(d time.Duration) {
+	BulkRequestDuration.Observe(d.Seconds())
+}
+
+// RecordBulkStatus classifies a single bulk response item's HTTP status into
+// 2xx/409/429/4xx/5xx and counts it.
+func RecordBulkStatus(status int) {
+	BulkItems.WithLabelValues(statusClass(status)).Inc()
+}
+
+func statusClass(status int) string {
+	switch status {
+	case 409:
+		return "409"
+	case 429:
+		return "429"
+	}
+	switch status / 100 {
+	case 2:
+		return "2xx"
+	case 4:
+		return "4xx"
+	case 5:
+		return "5xx"
+	default:
+		return "unknown"
+	}
+}
+
+// RecordBookUpdate records the current spread for market, for the
+// gdax_trader_order_book_spread and gdax_trader_top_of_book_age_seconds
+// metrics.
+func RecordBookUpdate(market string, spread float64) {
+	books.mu.Lock()
+	defer books.mu.Unlock()
+	books.lastUpdate[market] = time.Now()
+	books.spread[market] = spread
+}
+
+// StreamsHealthy reports whether every market/channel that has reported at
+// least one message has done so within threshold. It's used to decide
+// readiness: a stream that's gone quiet for longer than threshold means its
+// websocket connection is stuck, even if the process itself is still up.
+func StreamsHealthy(threshold time.Duration) bool {
+	streams.mu.Lock()
+	defer streams.mu.Unlock()
+
+	now := time.Now()
+	for name, ts := range streams.lastSeen {
+		if age := now.Sub(ts); age > threshold {
+			logMetrics.Warn("Stream " + name + " has not reported in " + age.String())
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/pwaterz/gdax-trader/pkg/config"
+	"github.com/pwaterz/gdax-trader/pkg/log"
+)
+
+var logHTTP = log.For("http")
+
+// Server exposes /metrics, /healthz and /readyz over HTTP.
+type Server struct {
+	httpServer *http.Server
+	threshold  time.Duration
+}
+
+// New returns a Server configured to listen on cfg.ListenAddress.
+// cfg.DisconnectThresholdSeconds (default 120) controls how long a market's stream can go quiet
+// before /readyz reports not-ready.
+func New(cfg *config.MetricsConfig) *Server {
+	threshold := time.Duration(cfg.DisconnectThresholdSeconds) * time.Second
+	if threshold == 0 {
+		threshold = 2 * time.Minute
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	s := &Server{threshold: threshold}
+	mux.HandleFunc("/readyz", s.readyz)
+
+	s.httpServer = &http.Server{
+		Addr:    cfg.ListenAddress,
+		Handler: mux,
+	}
+	return s
+}
+
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	if !StreamsHealthy(s.threshold) {
+		http.Error(w, "one or more streams disconnected", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Run starts the HTTP server and blocks until ctx is cancelled, then shuts
+// it down gracefully.
+func (s *Server) Run(ctx context.Context) error {
+	errc := make(chan error, 1)
+	go func() {
+		logHTTP.Info("Serving metrics and health checks on " + s.httpServer.Addr)
+		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errc <- err
+			return
+		}
+		errc <- nil
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return s.httpServer.Shutdown(shutdownCtx)
+	case err := <-errc:
+		return err
+	}
+}
@@ -0,0 +1,232 @@
+// Package orderbook maintains an in-memory limit order book per market,
+// built from a GDAX level2 "snapshot" message followed by "l2update" deltas.
+package orderbook
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ErrUnknownSide is returned when an update references a side other than "buy"/"sell".
+var ErrUnknownSide = errors.New("orderbook: unknown side")
+
+// PriceLevel is a single price/size pair on one side of the book.
+type PriceLevel struct {
+	Price float64
+	Size  float64
+}
+
+// Book is an in-memory order book for a single product. Bids are kept sorted
+// descending by price and asks ascending, as plain slices: inserts/removes are
+// found with a binary search (O(log n)) and shift the backing array, so
+// top-of-book reads stay O(1) without the bookkeeping of a balanced tree.
+// Safe for concurrent use: an internal mutex guards every access, since the
+// Indexer reads the book from its flush loop while the read loop concurrently
+// applies updates on a separate goroutine.
+type Book struct {
+	mu   sync.RWMutex
+	bids []PriceLevel
+	asks []PriceLevel
+
+	// volume is the cumulative size touched by every applied update, i.e. a
+	// running measure of order book activity rather than traded volume.
+	volume float64
+}
+
+// NewBook returns an empty order book.
+func NewBook() *Book {
+	return &Book{}
+}
+
+// LoadSnapshot resets the book from a level2 "snapshot" message's raw
+// [price, size] string pairs.
+func (b *Book) LoadSnapshot(bids, asks [][]string) error {
+	newBids, err := parseLevels(bids)
+	if err != nil {
+		return err
+	}
+	newAsks, err := parseLevels(asks)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(newBids, func(i, j int) bool { return newBids[i].Price > newBids[j].Price })
+	sort.Slice(newAsks, func(i, j int) bool { return newAsks[i].Price < newAsks[j].Price })
+
+	b.mu.Lock()
+	b.bids = newBids
+	b.asks = newAsks
+	b.mu.Unlock()
+	return nil
+}
+
+func parseLevels(raw [][]string) ([]PriceLevel, error) {
+	levels := make([]PriceLevel, 0, len(raw))
+	for _, pair := range raw {
+		if len(pair) != 2 {
+			continue
+		}
+		price, err := strconv.ParseFloat(pair[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.ParseFloat(pair[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, PriceLevel{Price: price, Size: size})
+	}
+	return levels, nil
+}
+
+// ApplyUpdate applies a single l2update change (side, price, size). A size of
+// 0 removes the level. Updates must be applied in arrival order.
+func (b *Book) ApplyUpdate(side, priceStr, sizeStr string) error {
+	price, err := strconv.ParseFloat(priceStr, 64)
+	if err != nil {
+		return err
+	}
+	size, err := strconv.ParseFloat(sizeStr, 64)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch side {
+	case "buy":
+		b.bids = setLevel(b.bids, price, size, true)
+	case "sell":
+		b.asks = setLevel(b.asks, price, size, false)
+	default:
+		return ErrUnknownSide
+	}
+
+	b.volume += size
+	return nil
+}
+
+// setLevel inserts, updates or removes price in a slice kept sorted by
+// descending (bids) or ascending (asks) price.
+func setLevel(levels []PriceLevel, price, size float64, descending bool) []PriceLevel {
+	i := sort.Search(len(levels), func(i int) bool {
+		if descending {
+			return levels[i].Price <= price
+		}
+		return levels[i].Price >= price
+	})
+
+	found := i < len(levels) && levels[i].Price == price
+	if size == 0 {
+		if found {
+			levels = append(levels[:i], levels[i+1:]...)
+		}
+		return levels
+	}
+
+	if found {
+		levels[i].Size = size
+		return levels
+	}
+
+	levels = append(levels, PriceLevel{})
+	copy(levels[i+1:], levels[i:])
+	levels[i] = PriceLevel{Price: price, Size: size}
+	return levels
+}
+
+// TopBids returns up to n price levels from the top of the bid side.
+func (b *Book) TopBids(n int) []PriceLevel {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return top(b.bids, n)
+}
+
+// TopAsks returns up to n price levels from the top of the ask side.
+func (b *Book) TopAsks(n int) []PriceLevel {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return top(b.asks, n)
+}
+
+// Volume returns the cumulative size touched by every applied update, i.e. a
+// running measure of order book activity rather than traded volume.
+func (b *Book) Volume() float64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.volume
+}
+
+func top(levels []PriceLevel, n int) []PriceLevel {
+	if n > len(levels) {
+		n = len(levels)
+	}
+	out := make([]PriceLevel, n)
+	copy(out, levels[:n])
+	return out
+}
+
+// Mid returns the mid price between best bid and best ask. ok is false if
+// either side of the book is empty.
+func (b *Book) Mid() (mid float64, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.mid()
+}
+
+// mid is Mid without locking, for use by methods that already hold b.mu.
+func (b *Book) mid() (mid float64, ok bool) {
+	if len(b.bids) == 0 || len(b.asks) == 0 {
+		return 0, false
+	}
+	return (b.bids[0].Price + b.asks[0].Price) / 2, true
+}
+
+// Spread returns the best ask minus the best bid. ok is false if either side
+// of the book is empty.
+func (b *Book) Spread() (spread float64, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.bids) == 0 || len(b.asks) == 0 {
+		return 0, false
+	}
+	return b.asks[0].Price - b.bids[0].Price, true
+}
+
+// DepthAtBps sums the size resting within bps basis points of the mid price
+// on the given side ("buy" or "sell").
+func (b *Book) DepthAtBps(side string, bps float64) float64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	mid, ok := b.mid()
+	if !ok {
+		return 0
+	}
+	band := mid * bps / 10000
+
+	var levels []PriceLevel
+	var bound float64
+	if side == "buy" {
+		levels = b.bids
+		bound = mid - band
+	} else {
+		levels = b.asks
+		bound = mid + band
+	}
+
+	var depth float64
+	for _, l := range levels {
+		if side == "buy" && l.Price < bound {
+			break
+		}
+		if side == "sell" && l.Price > bound {
+			break
+		}
+		depth += l.Size
+	}
+	return depth
+}
@@ -0,0 +1,208 @@
+package orderbook
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	ws "github.com/gorilla/websocket"
+	gdax "github.com/preichenberger/go-gdax"
+
+	"github.com/pwaterz/gdax-trader/pkg/log"
+	"github.com/pwaterz/gdax-trader/pkg/metrics"
+	"github.com/pwaterz/gdax-trader/pkg/output"
+)
+
+var logStream = log.For("orderbook")
+
+// Config controls how an Indexer derives and flushes order book documents.
+type Config struct {
+	// TopN is how many price levels per side to include in each flushed document.
+	TopN int
+	// FlushInterval is how often the current book is snapshotted and queued for writing.
+	FlushInterval time.Duration
+	// DepthBands are the basis-point bands that bid/ask depth is reported at.
+	DepthBands []float64
+	// QueueSize is the capacity of the buffer between book consumption and output sink writes.
+	QueueSize int
+}
+
+// DefaultConfig returns the configuration used when one isn't supplied.
+func DefaultConfig() Config {
+	return Config{
+		TopN:          10,
+		FlushInterval: 1 * time.Second,
+		DepthBands:    []float64{10, 50, 100},
+		QueueSize:     64,
+	}
+}
+
+// doc is the derived, flushed representation of a Book at a point in time.
+type doc struct {
+	ProductID string         `json:"product_id"`
+	Time      time.Time      `json:"time"`
+	Bids      []PriceLevel   `json:"bids"`
+	Asks      []PriceLevel   `json:"asks"`
+	Mid       float64        `json:"mid_price,omitempty"`
+	Spread    float64        `json:"spread,omitempty"`
+	Depth     map[string]bps `json:"depth"`
+	Volume    float64        `json:"cumulative_volume"`
+}
+
+type bps struct {
+	Bid float64 `json:"bid"`
+	Ask float64 `json:"ask"`
+}
+
+// Indexer consumes the level2 stream for a single product, keeps a Book up to
+// date, and periodically flushes derived documents to an output sink. Stream
+// consumption and sink writes are decoupled by a buffered channel so a slow
+// sink never blocks the socket read loop.
+type Indexer struct {
+	product string
+	book    *Book
+	out     output.Output
+	cfg     Config
+	docs    chan doc
+}
+
+// NewIndexer creates an Indexer for product, writing derived documents to out.
+func NewIndexer(product string, out output.Output, cfg Config) *Indexer {
+	return &Indexer{
+		product: product,
+		book:    NewBook(),
+		out:     out,
+		cfg:     cfg,
+		docs:    make(chan doc, cfg.QueueSize),
+	}
+}
+
+// Run dials the level2 feed, applies the snapshot and subsequent l2update
+// deltas to the book, and flushes derived documents until ctx is cancelled
+// or the socket breaks. Either way, Run cancels its own writeLoop/flushLoop
+// as it returns, so a caller that restarts Run on a fresh Indexer (e.g.
+// a reconnect supervisor) never leaks the previous run's goroutines.
+func (ix *Indexer) Run(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wsDialer ws.Dialer
+	wsConn, _, err := wsDialer.Dial("wss://ws-feed.gdax.com", nil)
+	if err != nil {
+		return err
+	}
+	defer wsConn.Close()
+
+	subscribe := gdax.Message{
+		Type: "subscribe",
+		Channels: []gdax.MessageChannel{
+			gdax.MessageChannel{
+				Name:       "level2",
+				ProductIds: []string{ix.product},
+			},
+		},
+	}
+	if err := wsConn.WriteJSON(subscribe); err != nil {
+		return err
+	}
+
+	metrics.RecordReconnect(ix.product, "level2")
+
+	go func() {
+		<-runCtx.Done()
+		wsConn.Close()
+	}()
+
+	go ix.writeLoop(runCtx)
+	go ix.flushLoop(runCtx)
+
+	return ix.readLoop(wsConn)
+}
+
+// readLoop consumes every message off the socket in arrival order, with no
+// ticker-based sampling, and applies it to the book.
+func (ix *Indexer) readLoop(wsConn *ws.Conn) error {
+	message := gdax.Message{}
+	for {
+		if err := wsConn.ReadJSON(&message); err != nil {
+			return err
+		}
+		metrics.RecordMessageReceived(ix.product, "level2")
+
+		switch message.Type {
+		case "snapshot":
+			if err := ix.book.LoadSnapshot(message.Bids, message.Asks); err != nil {
+				logStream.Error(err)
+			}
+		case "l2update":
+			for _, change := range message.Changes {
+				if len(change) != 3 {
+					continue
+				}
+				if err := ix.book.ApplyUpdate(change[0], change[1], change[2]); err != nil {
+					logStream.Error(err)
+				}
+			}
+		}
+	}
+}
+
+// flushLoop periodically snapshots the book and queues a derived document.
+func (ix *Indexer) flushLoop(ctx context.Context) {
+	ticker := time.NewTicker(ix.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			close(ix.docs)
+			return
+		case <-ticker.C:
+			d := ix.snapshotDoc()
+			select {
+			case ix.docs <- d:
+			case <-ctx.Done():
+			}
+		}
+	}
+}
+
+func (ix *Indexer) snapshotDoc() doc {
+	mid, _ := ix.book.Mid()
+	spread, _ := ix.book.Spread()
+	metrics.RecordBookUpdate(ix.product, spread)
+
+	depth := make(map[string]bps, len(ix.cfg.DepthBands))
+	for _, band := range ix.cfg.DepthBands {
+		key := strconvBps(band)
+		depth[key] = bps{
+			Bid: ix.book.DepthAtBps("buy", band),
+			Ask: ix.book.DepthAtBps("sell", band),
+		}
+	}
+
+	return doc{
+		ProductID: ix.product,
+		Time:      time.Now().UTC(),
+		Bids:      ix.book.TopBids(ix.cfg.TopN),
+		Asks:      ix.book.TopAsks(ix.cfg.TopN),
+		Mid:       mid,
+		Spread:    spread,
+		Depth:     depth,
+		Volume:    ix.book.Volume(),
+	}
+}
+
+// writeLoop drains queued documents and writes them to the output sink,
+// decoupled from the socket read loop.
+func (ix *Indexer) writeLoop(ctx context.Context) {
+	for d := range ix.docs {
+		if err := ix.out.Write(ctx, "order-book", d); err != nil {
+			logStream.Error(err)
+		}
+	}
+}
+
+func strconvBps(bps float64) string {
+	return strconv.FormatFloat(bps, 'f', -1, 64) + "bps"
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"context"
+
+	elastic "gopkg.in/olivere/elastic.v5"
+
+	"github.com/pwaterz/gdax-trader/pkg/backoff"
+)
+
+// ElasticOutput writes documents to an elastic bulk processor, the original
+// (and still default) sink for this tool.
+type ElasticOutput struct {
+	indexName string
+	bulk      *elastic.BulkProcessor
+	backoff   *backoff.Backoff
+}
+
+// NewElasticOutput returns an Output backed by an already-started bulk
+// processor. backoff is paused by the processor's After callback when the
+// cluster reports overload, and Write waits on it before queuing more
+// documents.
+func NewElasticOutput(indexName string, bulk *elastic.BulkProcessor, backoff *backoff.Backoff) *ElasticOutput {
+	return &ElasticOutput{indexName: indexName, bulk: bulk, backoff: backoff}
+}
+
+// Write implements Output.
+func (e *ElasticOutput) Write(ctx context.Context, doctype string, doc interface{}) error {
+	if err := e.backoff.Wait(ctx); err != nil {
+		return err
+	}
+
+	r := elastic.NewBulkIndexRequest().
+		Index(e.indexName).
+		Type(doctype).
+		Doc(doc)
+	e.bulk.Add(r)
+	return nil
+}
+
+// Flush implements Output.
+func (e *ElasticOutput) Flush() error {
+	return e.bulk.Flush()
+}
+
+// Close implements Output. The bulk processor itself is started and stopped
+// by the caller that created it, since it's shared with index bootstrapping.
+func (e *ElasticOutput) Close() error {
+	return nil
+}
@@ -0,0 +1,107 @@
+package output
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileOutput appends one line per document to a file: the doctype, a tab,
+// then the document as JSON, so lines are not bare JSON. Once the file
+// reaches MaxBytes it is renamed with a timestamp suffix and writing
+// continues in a fresh file at the original path.
+type FileOutput struct {
+	path     string
+	maxBytes int64
+
+	mu      sync.Mutex
+	file    *os.File
+	writer  *bufio.Writer
+	written int64
+}
+
+// NewFileOutput opens (or creates) path for appending, rolling once it
+// reaches maxBytes. A maxBytes of 0 disables rolling.
+func NewFileOutput(path string, maxBytes int64) (*FileOutput, error) {
+	f := &FileOutput{path: path, maxBytes: maxBytes}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *FileOutput) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.writer = bufio.NewWriter(file)
+	f.written = info.Size()
+	return nil
+}
+
+// Write implements Output.
+func (f *FileOutput) Write(ctx context.Context, doctype string, doc interface{}) error {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.maxBytes > 0 && f.written >= f.maxBytes {
+		if err := f.rollLocked(); err != nil {
+			return err
+		}
+	}
+
+	n, err := fmt.Fprintf(f.writer, "%s\t%s\n", doctype, raw)
+	f.written += int64(n)
+	return err
+}
+
+// rollLocked flushes and closes the current file, renaming it with a
+// timestamp suffix, then opens a fresh file at the original path. Callers
+// must hold f.mu.
+func (f *FileOutput) rollLocked() error {
+	if err := f.writer.Flush(); err != nil {
+		return err
+	}
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	rolled := fmt.Sprintf("%s.%s", f.path, time.Now().UTC().Format("20060102T150405"))
+	if err := os.Rename(f.path, rolled); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Flush implements Output.
+func (f *FileOutput) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.writer.Flush()
+}
+
+// Close implements Output.
+func (f *FileOutput) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.writer.Flush(); err != nil {
+		return err
+	}
+	return f.file.Close()
+}
@@ -0,0 +1,161 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	influx "github.com/influxdata/influxdb/client/v2"
+
+	"github.com/pwaterz/gdax-trader/pkg/log"
+)
+
+var logOutput = log.For("output")
+
+// defaultInfluxFlushInterval is used when a caller doesn't set one.
+const defaultInfluxFlushInterval = 10 * time.Second
+
+// InfluxDBOutput writes documents as line-protocol points, buffering them
+// into a batch that Flush, a background ticker, or Close sends to the
+// server. Fields are whatever JSON-marshals the doc to a flat map of
+// numbers/strings/bools; nested documents are dropped since Influx has no
+// concept of them.
+type InfluxDBOutput struct {
+	client   influx.Client
+	database string
+
+	mu    sync.Mutex
+	batch influx.BatchPoints
+
+	done chan struct{}
+}
+
+// NewInfluxDBOutput returns an Output that writes to the given InfluxDB
+// address (e.g. "http://localhost:8086") and database, creating it if it
+// doesn't already exist is left to the operator. The batch is flushed to the
+// server on its own goroutine every flushInterval; flushInterval <= 0 uses
+// defaultInfluxFlushInterval.
+func NewInfluxDBOutput(addr, database, username, password string, flushInterval time.Duration) (*InfluxDBOutput, error) {
+	client, err := influx.NewHTTPClient(influx.HTTPConfig{
+		Addr:     addr,
+		Username: username,
+		Password: password,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &InfluxDBOutput{client: client, database: database, done: make(chan struct{})}
+	if err := out.newBatchLocked(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	if flushInterval <= 0 {
+		flushInterval = defaultInfluxFlushInterval
+	}
+	go out.flushLoop(flushInterval)
+
+	return out, nil
+}
+
+// flushLoop periodically sends the accumulated batch until Close stops it.
+func (i *InfluxDBOutput) flushLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := i.Flush(); err != nil {
+				logOutput.Error(err)
+			}
+		case <-i.done:
+			return
+		}
+	}
+}
+
+func (i *InfluxDBOutput) newBatchLocked() error {
+	batch, err := influx.NewBatchPoints(influx.BatchPointsConfig{
+		Database:  i.database,
+		Precision: "ms",
+	})
+	if err != nil {
+		return err
+	}
+	i.batch = batch
+	return nil
+}
+
+// Write implements Output.
+func (i *InfluxDBOutput) Write(ctx context.Context, doctype string, doc interface{}) error {
+	fields, err := toFields(doc)
+	if err != nil {
+		return err
+	}
+
+	tags := map[string]string{}
+	if pid := productID(doc); pid != "" {
+		tags["product_id"] = pid
+	}
+
+	point, err := influx.NewPoint(doctype, tags, fields, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.batch.AddPoint(point)
+	return nil
+}
+
+// toFields flattens doc's JSON representation into the scalar map Influx
+// fields require, dropping nested objects/arrays.
+func toFields(doc interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var asMap map[string]interface{}
+	if err := json.Unmarshal(raw, &asMap); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{}, len(asMap))
+	for k, v := range asMap {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			continue
+		default:
+			fields[k] = v
+		}
+	}
+	return fields, nil
+}
+
+// Flush implements Output.
+func (i *InfluxDBOutput) Flush() error {
+	i.mu.Lock()
+	batch := i.batch
+	if err := i.newBatchLocked(); err != nil {
+		i.mu.Unlock()
+		return err
+	}
+	i.mu.Unlock()
+
+	return i.client.Write(batch)
+}
+
+// Close implements Output, stopping the background flush loop.
+func (i *InfluxDBOutput) Close() error {
+	close(i.done)
+	if err := i.Flush(); err != nil {
+		i.client.Close()
+		return err
+	}
+	return i.client.Close()
+}
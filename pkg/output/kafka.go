@@ -0,0 +1,57 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Shopify/sarama"
+)
+
+// KafkaOutput publishes documents to Kafka, one topic per doctype, keyed by
+// product id so that all updates for a given market land on the same
+// partition and stay in order.
+type KafkaOutput struct {
+	producer sarama.SyncProducer
+}
+
+// NewKafkaOutput returns an Output that publishes to brokers using a
+// synchronous producer with the given client name.
+func NewKafkaOutput(brokers []string, clientID string) (*KafkaOutput, error) {
+	cfg := sarama.NewConfig()
+	cfg.ClientID = clientID
+	cfg.Producer.Return.Successes = true
+	cfg.Producer.RequiredAcks = sarama.WaitForLocal
+
+	producer, err := sarama.NewSyncProducer(brokers, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaOutput{producer: producer}, nil
+}
+
+// Write implements Output.
+func (k *KafkaOutput) Write(ctx context.Context, doctype string, doc interface{}) error {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: doctype,
+		Key:   sarama.StringEncoder(productID(doc)),
+		Value: sarama.ByteEncoder(raw),
+	}
+	_, _, err = k.producer.SendMessage(msg)
+	return err
+}
+
+// Flush implements Output. The synchronous producer has no internal buffer
+// to flush; every SendMessage call already waits for the configured acks.
+func (k *KafkaOutput) Flush() error {
+	return nil
+}
+
+// Close implements Output.
+func (k *KafkaOutput) Close() error {
+	return k.producer.Close()
+}
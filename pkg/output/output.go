@@ -0,0 +1,83 @@
+// Package output abstracts where indexed documents end up, so the
+// websocket-consuming indexers don't have to know about Elasticsearch
+// specifically.
+package output
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Output is a destination for derived ticker/order-book documents.
+type Output interface {
+	// Write sends doc, tagged with doctype (e.g. "ticker", "order-book"), to
+	// the sink.
+	Write(ctx context.Context, doctype string, doc interface{}) error
+	// Flush forces any buffered documents to be sent.
+	Flush() error
+	// Close flushes and releases any resources held by the sink.
+	Close() error
+}
+
+// productID best-effort extracts a "product_id" field from doc by
+// round-tripping it through JSON, so sinks that want to partition or key by
+// product (e.g. Kafka) don't need every document type to implement an
+// interface.
+func productID(doc interface{}) string {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return ""
+	}
+
+	var fields struct {
+		ProductID string `json:"product_id"`
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return ""
+	}
+	return fields.ProductID
+}
+
+// MultiOutput fans a single Write out to every underlying Output. Write
+// returns the first error encountered but still writes to every sink.
+type MultiOutput struct {
+	Outputs []Output
+}
+
+// NewMultiOutput returns an Output that fans out to every given sink.
+func NewMultiOutput(outputs ...Output) *MultiOutput {
+	return &MultiOutput{Outputs: outputs}
+}
+
+// Write implements Output.
+func (m *MultiOutput) Write(ctx context.Context, doctype string, doc interface{}) error {
+	var firstErr error
+	for _, o := range m.Outputs {
+		if err := o.Write(ctx, doctype, doc); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Flush implements Output.
+func (m *MultiOutput) Flush() error {
+	var firstErr error
+	for _, o := range m.Outputs {
+		if err := o.Flush(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close implements Output.
+func (m *MultiOutput) Close() error {
+	var firstErr error
+	for _, o := range m.Outputs {
+		if err := o.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
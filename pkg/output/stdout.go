@@ -0,0 +1,42 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// StdoutOutput writes one line per document to a writer (os.Stdout by
+// default): the doctype, a tab, then the document as JSON. Useful for
+// debugging without an Elasticsearch cluster.
+type StdoutOutput struct {
+	w io.Writer
+}
+
+// NewStdoutOutput returns an Output that writes doctype-tagged JSON lines to
+// os.Stdout.
+func NewStdoutOutput() *StdoutOutput {
+	return &StdoutOutput{w: os.Stdout}
+}
+
+// Write implements Output.
+func (s *StdoutOutput) Write(ctx context.Context, doctype string, doc interface{}) error {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, "%s\t%s\n", doctype, raw)
+	return err
+}
+
+// Flush implements Output. Writes to stdout are unbuffered, so this is a no-op.
+func (s *StdoutOutput) Flush() error {
+	return nil
+}
+
+// Close implements Output. os.Stdout is never closed.
+func (s *StdoutOutput) Close() error {
+	return nil
+}
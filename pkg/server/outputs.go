@@ -0,0 +1,70 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	es "gopkg.in/olivere/elastic.v5"
+
+	"github.com/pwaterz/gdax-trader/pkg/api"
+	"github.com/pwaterz/gdax-trader/pkg/backoff"
+	"github.com/pwaterz/gdax-trader/pkg/config"
+	"github.com/pwaterz/gdax-trader/pkg/output"
+)
+
+// buildOutput constructs the configured output sinks and fans out to all of
+// them. bulkProcessor and esBackoff are only used if an "elastic" sink is
+// configured (or no sinks are configured at all, to keep existing
+// configurations working).
+func buildOutput(cfg *config.Configuration, bulkProcessor *es.BulkProcessor, esBackoff *backoff.Backoff) (output.Output, error) {
+	configs := cfg.Outputs
+	if len(configs) == 0 {
+		configs = []config.OutputConfig{{Type: "elastic"}}
+	}
+
+	outputs := make([]output.Output, 0, len(configs))
+	for _, oc := range configs {
+		out, err := buildSingleOutput(oc, cfg, bulkProcessor, esBackoff)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, out)
+	}
+
+	if len(outputs) == 1 {
+		return outputs[0], nil
+	}
+	return output.NewMultiOutput(outputs...), nil
+}
+
+func buildSingleOutput(oc config.OutputConfig, cfg *config.Configuration, bulkProcessor *es.BulkProcessor, esBackoff *backoff.Backoff) (output.Output, error) {
+	switch oc.Type {
+	case "", "elastic":
+		return output.NewElasticOutput(cfg.ElasticIndexName, bulkProcessor, esBackoff), nil
+	case "stdout":
+		return output.NewStdoutOutput(), nil
+	case "file":
+		if oc.File == nil {
+			return nil, fmt.Errorf("output: file sink requires a \"file\" configuration block")
+		}
+		return output.NewFileOutput(oc.File.Path, oc.File.MaxBytes)
+	case "kafka":
+		if oc.Kafka == nil {
+			return nil, fmt.Errorf("output: kafka sink requires a \"kafka\" configuration block")
+		}
+		return output.NewKafkaOutput(oc.Kafka.Brokers, oc.Kafka.ClientID)
+	case "influxdb":
+		if oc.InfluxDB == nil {
+			return nil, fmt.Errorf("output: influxdb sink requires an \"influxdb\" configuration block")
+		}
+		flushInterval := time.Duration(oc.InfluxDB.FlushIntervalSeconds) * time.Second
+		return output.NewInfluxDBOutput(oc.InfluxDB.Address, oc.InfluxDB.Database, oc.InfluxDB.Username, oc.InfluxDB.Password, flushInterval)
+	case "grpc":
+		if oc.GRPC == nil {
+			return nil, fmt.Errorf("output: grpc sink requires a \"grpc\" configuration block")
+		}
+		return api.New(oc.GRPC)
+	default:
+		return nil, fmt.Errorf("output: unknown output type %q", oc.Type)
+	}
+}
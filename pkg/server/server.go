@@ -0,0 +1,145 @@
+// Package server owns this tool's lifecycle: connecting to elastic,
+// building output sinks, and running one supervised stream per configured
+// market until told to stop.
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pwaterz/gdax-trader/pkg/backoff"
+	"github.com/pwaterz/gdax-trader/pkg/config"
+	"github.com/pwaterz/gdax-trader/pkg/elastic"
+	"github.com/pwaterz/gdax-trader/pkg/gdax"
+	"github.com/pwaterz/gdax-trader/pkg/log"
+	"github.com/pwaterz/gdax-trader/pkg/metrics"
+	"github.com/pwaterz/gdax-trader/pkg/output"
+	"github.com/pwaterz/gdax-trader/pkg/supervisor"
+)
+
+var logMain = log.For("main")
+
+// elasticTemplateFile is the index template applied when the configured
+// index doesn't already exist.
+const elasticTemplateFile = "elastic-template.json"
+
+const (
+	// minStreamBackoff/maxStreamBackoff bound how long a supervisor waits
+	// between restarts of a websocket stream; healthyStreamAfter is how long
+	// a stream has to stay up before that backoff resets.
+	minStreamBackoff   = 1 * time.Second
+	maxStreamBackoff   = 60 * time.Second
+	healthyStreamAfter = 2 * time.Minute
+)
+
+// Server runs the configured market indexers until stopped.
+type Server struct {
+	cfg *config.Configuration
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+
+	// esBackoff is shared by every output that writes to Elasticsearch; the
+	// bulk processor's After callback pauses it when the cluster reports
+	// overload, which in turn makes those outputs' Write calls block.
+	esBackoff *backoff.Backoff
+}
+
+// New validates cfg and returns a Server ready to Run.
+func New(cfg *config.Configuration) (*Server, error) {
+	if len(cfg.GDAXMarkets) == 0 {
+		return nil, errors.New("configuration has no gdax-markets to index")
+	}
+
+	log.SetLevel(cfg.LogLevel)
+
+	return &Server{
+		cfg:       cfg,
+		esBackoff: backoff.New(1*time.Second, 60*time.Second),
+	}, nil
+}
+
+// Run connects to elastic, builds the configured output sinks, and starts
+// one supervised indexer per market and channel. It blocks until ctx is
+// cancelled or Stop is called, then waits for every indexer to exit before
+// returning.
+func (s *Server) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	defer cancel()
+
+	client, err := elastic.NewClient(s.cfg)
+	if err != nil {
+		return errors.Wrap(err, "could not connect to elastic cluster")
+	}
+
+	bulkProcessor, err := elastic.NewBulkProcessor(ctx, client, s.cfg, s.esBackoff)
+	if err != nil {
+		return errors.Wrap(err, "could not start bulk processor")
+	}
+
+	if err := elastic.EnsureIndex(ctx, client, s.cfg, elasticTemplateFile); err != nil {
+		return errors.Wrap(err, "could not ensure elastic index exists")
+	}
+
+	out, err := buildOutput(s.cfg, bulkProcessor, s.esBackoff)
+	if err != nil {
+		return errors.Wrap(err, "could not build output sinks")
+	}
+
+	if s.cfg.Metrics != nil {
+		logMain.Info("Starting metrics server")
+		s.runSupervised(ctx, "metrics", metrics.New(s.cfg.Metrics).Run)
+	}
+
+	for _, market := range s.cfg.GDAXMarkets {
+		market := market
+
+		logMain.Info("Starting indexer for order book " + market)
+		s.runSupervised(ctx, "order-book-"+market, func(ctx context.Context) error {
+			return gdax.IndexOrderBook(ctx, market, out)
+		})
+
+		logMain.Info("Starting indexer for ticker " + market)
+		s.runSupervised(ctx, "ticker-"+market, func(ctx context.Context) error {
+			return gdax.IndexTicker(ctx, market, out)
+		})
+	}
+
+	<-ctx.Done()
+	logMain.Info("Shutting down")
+
+	s.wg.Wait()
+
+	if err := out.Close(); err != nil {
+		logMain.Error(errors.Wrap(err, "error closing output sinks"))
+	}
+	if err := bulkProcessor.Close(); err != nil {
+		logMain.Error(errors.Wrap(err, "error closing elastic bulk processor"))
+	}
+	client.Stop()
+
+	return nil
+}
+
+// Stop cancels the context Run is working under, triggering a graceful
+// shutdown. Run creates that context, so calling Stop before Run has no
+// effect and a later Run call still runs until its own ctx is cancelled.
+func (s *Server) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// runSupervised runs fn under a supervisor with exponential backoff and
+// jitter until ctx is cancelled, tracked on the Server's shutdown WaitGroup.
+func (s *Server) runSupervised(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		supervisor.New(name, minStreamBackoff, maxStreamBackoff, healthyStreamAfter).Run(ctx, fn)
+	}()
+}
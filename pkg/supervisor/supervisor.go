@@ -0,0 +1,83 @@
+// Package supervisor restarts long-running stream goroutines with an
+// exponential backoff and jitter, instead of the fixed-delay
+// panic/recover loops this tool used to rely on.
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pwaterz/gdax-trader/pkg/backoff"
+	"github.com/pwaterz/gdax-trader/pkg/log"
+)
+
+var logStream = log.For("supervisor")
+
+// Supervisor restarts a single named function until its context is
+// cancelled, backing off between restarts and resetting that backoff once
+// the function has run cleanly for HealthyAfter.
+type Supervisor struct {
+	Name         string
+	HealthyAfter time.Duration
+	backoff      *backoff.Backoff
+}
+
+// New returns a Supervisor that backs off between minBackoff and maxBackoff,
+// resetting once a run has stayed up for at least healthyAfter.
+func New(name string, minBackoff, maxBackoff, healthyAfter time.Duration) *Supervisor {
+	return &Supervisor{
+		Name:         name,
+		HealthyAfter: healthyAfter,
+		backoff:      backoff.New(minBackoff, maxBackoff),
+	}
+}
+
+// Run calls fn repeatedly until ctx is done. A panic inside fn is treated
+// like a returned error. Between restarts it sleeps for a jittered,
+// exponentially growing delay; a run that survives HealthyAfter resets that
+// delay back to its minimum.
+func (s *Supervisor) Run(ctx context.Context, fn func(ctx context.Context) error) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		start := time.Now()
+		err := s.runOnce(ctx, fn)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(start) >= s.HealthyAfter {
+			s.backoff.Reset()
+		}
+
+		if err != nil {
+			logStream.WithField("stream", s.Name).Error(err)
+		}
+
+		wait := s.backoff.Next()
+		logStream.WithField("stream", s.Name).Infof("restarting in %s (failures: %d)", wait, s.backoff.Failures())
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// runOnce calls fn, converting a panic into an error so a single bad message
+// can't take the supervisor down with it.
+func (s *Supervisor) runOnce(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s: panic: %v", s.Name, r)
+		}
+	}()
+	return fn(ctx)
+}